@@ -3,7 +3,10 @@ package base64_test
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -14,6 +17,27 @@ import (
 	"github.com/yupsh/base64/opt"
 )
 
+// zeroReader yields an arbitrary number of zero bytes without ever
+// materializing them all at once, so it can stand in for a multi-GB file
+// without actually allocating one.
+type zeroReader struct {
+	remaining int64
+}
+
+func (z *zeroReader) Read(p []byte) (int, error) {
+	if z.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > z.remaining {
+		p = p[:z.remaining]
+	}
+	for i := range p {
+		p[i] = 0
+	}
+	z.remaining -= int64(len(p))
+	return len(p), nil
+}
+
 // Example tests (basic functionality)
 func ExampleBase64() {
 	ctx := context.Background()
@@ -85,8 +109,8 @@ func TestBase64_BasicDecode(t *testing.T) {
 func TestBase64_ContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Create large input that would take time to process
-	largeInput := strings.Repeat("This is test data that will be repeated many times. ", 10000)
+	// Create large input that will still be mid-stream when cancel fires.
+	largeInput := strings.Repeat("This is test data that will be repeated many times. ", 400000)
 	input := strings.NewReader(largeInput)
 
 	var output, stderr bytes.Buffer
@@ -116,10 +140,10 @@ func TestBase64_ContextCancellation(t *testing.T) {
 func TestBase64_DecodeContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Create large base64 input
-	largeData := strings.Repeat("This is test data. ", 10000)
-	_ = largeData // Use the variable to avoid unused error
-	input := strings.NewReader(strings.Repeat("dGVzdA==", 10000))
+	// Create large, validly-concatenated (no mid-stream padding) base64
+	// input as a single unwrapped line, large enough to still be mid-stream
+	// when cancel fires.
+	input := strings.NewReader(strings.Repeat("YWJj", 5000000))
 
 	var output, stderr bytes.Buffer
 	cmd := base64.Base64(opt.Decode)
@@ -222,7 +246,468 @@ func TestBase64_WrapWidth(t *testing.T) {
 	}
 }
 
+// TestBase64_StreamingConstantMemory proves encodeSource no longer buffers
+// the entire input: encoding a large synthetic stream should allocate a
+// small, roughly constant amount of memory regardless of input size.
+func TestBase64_StreamingConstantMemory(t *testing.T) {
+	const inputSize = 256 * 1024 * 1024 // 256MB, never materialized at once
+
+	ctx := context.Background()
+	cmd := base64.Base64()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	err := cmd.Execute(ctx, &zeroReader{remaining: inputSize}, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	runtime.ReadMemStats(&after)
+	allocated := after.TotalAlloc - before.TotalAlloc
+
+	if allocated > inputSize/4 {
+		t.Errorf("encodeSource allocated %d bytes for a %d byte input; expected roughly constant memory", allocated, inputSize)
+	}
+}
+
+// zeroBase64Reader yields an arbitrary number of the valid std-base64 byte
+// 'A' without ever materializing them all at once, so decode's streaming
+// path can be exercised against a multi-GB payload without allocating one.
+type zeroBase64Reader struct {
+	remaining int64
+}
+
+func (z *zeroBase64Reader) Read(p []byte) (int, error) {
+	if z.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > z.remaining {
+		p = p[:z.remaining]
+	}
+	for i := range p {
+		p[i] = 'A'
+	}
+	z.remaining -= int64(len(p))
+	return len(p), nil
+}
+
+// TestBase64_DecodeStreamingConstantMemory proves decodeSource no longer
+// depends on bufio.Scanner buffering a whole line: decoding a large,
+// unwrapped synthetic payload should allocate a small, roughly constant
+// amount of memory regardless of input size.
+func TestBase64_DecodeStreamingConstantMemory(t *testing.T) {
+	const inputSize = 256 * 1024 * 1024 // 256MB, never materialized at once
+
+	ctx := context.Background()
+	cmd := base64.Base64(opt.Decode)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	err := cmd.Execute(ctx, &zeroBase64Reader{remaining: inputSize}, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	runtime.ReadMemStats(&after)
+	allocated := after.TotalAlloc - before.TotalAlloc
+
+	if allocated > inputSize/4 {
+		t.Errorf("decodeSource allocated %d bytes for a %d byte input; expected roughly constant memory", allocated, inputSize)
+	}
+}
+
+// TestBase64_PEMDecodeLargeBlock proves decodePEMSource no longer needs to
+// buffer a whole PEM block before decoding it: a single large block decodes
+// to the expected output instead of requiring an ever-growing in-memory
+// accumulator.
+func TestBase64_PEMDecodeLargeBlock(t *testing.T) {
+	const inputSize = 16 * 1024 * 1024 // 16MB, encoded into one PEM block
+
+	ctx := context.Background()
+
+	var pem bytes.Buffer
+	encodeCmd := base64.Base64(opt.PEM, opt.Label("TEST"))
+	if err := encodeCmd.Execute(ctx, &zeroReader{remaining: inputSize}, &pem, io.Discard); err != nil {
+		t.Fatalf("Unexpected encode error: %v", err)
+	}
+
+	var decoded bytes.Buffer
+	decodeCmd := base64.Base64(opt.Decode, opt.PEM)
+	if err := decodeCmd.Execute(ctx, bytes.NewReader(pem.Bytes()), &decoded, io.Discard); err != nil {
+		t.Fatalf("Unexpected decode error: %v", err)
+	}
+
+	if decoded.Len() != inputSize {
+		t.Errorf("Expected %d decoded bytes, got %d", inputSize, decoded.Len())
+	}
+	for i, b := range decoded.Bytes() {
+		if b != 0 {
+			t.Fatalf("Expected all-zero decoded content, got byte %d at offset %d", b, i)
+		}
+	}
+}
+
+// Encoding alphabet tests
+func TestBase64_Encodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding opt.Encoding
+		input    string
+		expected string
+	}{
+		{"std base64", opt.StdBase64, "hello?", "aGVsbG8/"},
+		{"url base64", opt.URLBase64, "hello?", "aGVsbG8_"},
+		{"std base64 one-byte remainder (padded)", opt.StdBase64, "h", "aA=="},
+		{"std base64 two-byte remainder (padded)", opt.StdBase64, "hi", "aGk="},
+		{"url base64 one-byte remainder (padded)", opt.URLBase64, "h", "aA=="},
+		{"url base64 two-byte remainder (padded)", opt.URLBase64, "hi", "aGk="},
+		{"raw std base64", opt.RawStdBase64, "hi", "aGk"},
+		{"raw url base64", opt.RawURLBase64, "hi", "aGk"},
+		{"base32", opt.Base32, "hello", "NBSWY3DP"},
+		{"base32 hex", opt.Base32Hex, "hello", "D1IMOR3F"},
+		{"base16/hex", opt.Base16, "hi", "6869"},
+		{"ascii85", opt.Ascii85, "hello", "BOu!rDZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			input := strings.NewReader(tt.input)
+			var output, stderr bytes.Buffer
+
+			cmd := base64.Base64(tt.encoding)
+			err := cmd.Execute(ctx, input, &output, &stderr)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got := strings.TrimSpace(output.String())
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+
+			// Round-trip through decode.
+			decodeInput := strings.NewReader(got)
+			var decoded, decodeStderr bytes.Buffer
+			decodeCmd := base64.Base64(tt.encoding, opt.Decode)
+			if err := decodeCmd.Execute(ctx, decodeInput, &decoded, &decodeStderr); err != nil {
+				t.Fatalf("Unexpected decode error: %v", err)
+			}
+			if got := decoded.String(); got != tt.input {
+				t.Errorf("Round-trip: expected %q, got %q", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestBase64_EncodingIgnoreGarbage(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding opt.Encoding
+		input    string
+		expected string
+	}{
+		{"std base64", opt.StdBase64, "aG VsbG8=!!", "hello"},
+		{"base32", opt.Base32, "NB SWY3DP!!", "hello"},
+		{"hex", opt.Base16, "68 69!!", "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			input := strings.NewReader(tt.input)
+			var output, stderr bytes.Buffer
+
+			cmd := base64.Base64(tt.encoding, opt.Decode, opt.IgnoreGarbage)
+			err := cmd.Execute(ctx, input, &output, &stderr)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := output.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Framing tests
+func TestBase64_PEMFraming(t *testing.T) {
+	ctx := context.Background()
+	input := strings.NewReader("hello world")
+	var output, stderr bytes.Buffer
+
+	cmd := base64.Base64(opt.PEM, opt.Label("CERTIFICATE"))
+	if err := cmd.Execute(ctx, input, &output, &stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := output.String()
+	if !strings.HasPrefix(got, "-----BEGIN CERTIFICATE-----\r\n") {
+		t.Errorf("Missing PEM header, got %q", got)
+	}
+	if !strings.HasSuffix(got, "-----END CERTIFICATE-----\r\n") {
+		t.Errorf("Missing PEM footer, got %q", got)
+	}
+
+	var decoded, decodeStderr bytes.Buffer
+	decodeCmd := base64.Base64(opt.PEM, opt.Decode)
+	if err := decodeCmd.Execute(ctx, strings.NewReader(got), &decoded, &decodeStderr); err != nil {
+		t.Fatalf("Unexpected decode error: %v", err)
+	}
+	if got := decoded.String(); got != "hello world" {
+		t.Errorf("Round-trip: expected %q, got %q", "hello world", got)
+	}
+}
+
+func TestBase64_PEMFramingMultipleBlocks(t *testing.T) {
+	ctx := context.Background()
+	pem := "-----BEGIN KEY-----\r\naGVsbG8=\r\n-----END KEY-----\r\n" +
+		"-----BEGIN KEY-----\r\nd29ybGQ=\r\n-----END KEY-----\r\n"
+
+	var decoded, stderr bytes.Buffer
+	cmd := base64.Base64(opt.PEM, opt.Decode)
+	if err := cmd.Execute(ctx, strings.NewReader(pem), &decoded, &stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := decoded.String(); got != "helloworld" {
+		t.Errorf("Expected %q, got %q", "helloworld", got)
+	}
+}
+
+func TestBase64_MIMEFraming(t *testing.T) {
+	ctx := context.Background()
+	input := strings.NewReader(strings.Repeat("a", 100))
+	var output, stderr bytes.Buffer
+
+	cmd := base64.Base64(opt.MIME, opt.WrapWidth(16))
+	if err := cmd.Execute(ctx, input, &output, &stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := output.String()
+	if strings.HasPrefix(got, "-----BEGIN") {
+		t.Errorf("MIME framing should not emit a header, got %q", got)
+	}
+	if !strings.Contains(got, "\r\n") {
+		t.Errorf("Expected CRLF line endings, got %q", got)
+	}
+
+	var decoded, decodeStderr bytes.Buffer
+	decodeCmd := base64.Base64(opt.MIME, opt.Decode)
+	if err := decodeCmd.Execute(ctx, strings.NewReader(got), &decoded, &decodeStderr); err != nil {
+		t.Fatalf("Unexpected decode error: %v", err)
+	}
+	if got := decoded.String(); got != strings.Repeat("a", 100) {
+		t.Errorf("Round-trip mismatch")
+	}
+}
+
+// Checksum tests
+func TestBase64_ChecksumStderr(t *testing.T) {
+	ctx := context.Background()
+	input := strings.NewReader("hello world")
+	var output, stderr bytes.Buffer
+
+	cmd := base64.Base64(opt.SHA256)
+	if err := cmd.Execute(ctx, input, &output, &stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(stderr.String(), "base64: ") {
+		t.Errorf("Expected digest on stderr, got %q", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "stdin") {
+		t.Errorf("Expected stdin placeholder in stderr, got %q", stderr.String())
+	}
+}
+
+func TestBase64_ChecksumSuffixRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	input := strings.NewReader("hello world")
+	var output, stderr bytes.Buffer
+
+	cmd := base64.Base64(opt.SHA256, opt.Suffix)
+	if err := cmd.Execute(ctx, input, &output, &stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(output.String(), "# sha256=") {
+		t.Errorf("Expected checksum comment in output, got %q", output.String())
+	}
+
+	var decoded, decodeStderr bytes.Buffer
+	decodeCmd := base64.Base64(opt.SHA256, opt.Suffix, opt.Decode)
+	if err := decodeCmd.Execute(ctx, strings.NewReader(output.String()), &decoded, &decodeStderr); err != nil {
+		t.Fatalf("Unexpected decode error: %v", err)
+	}
+	if got := decoded.String(); got != "hello world" {
+		t.Errorf("Expected %q, got %q", "hello world", got)
+	}
+}
+
+func TestBase64_ChecksumSuffixMismatch(t *testing.T) {
+	ctx := context.Background()
+	// A valid encoded payload paired with a checksum comment for different data.
+	tampered := "aGVsbG8gd29ybGQ=\n# sha256=0000000000000000000000000000000000000000000000000000000000000000\n"
+
+	var decoded, stderr bytes.Buffer
+	cmd := base64.Base64(opt.SHA256, opt.Suffix, opt.Decode)
+	err := cmd.Execute(ctx, strings.NewReader(tampered), &decoded, &stderr)
+
+	if err == nil {
+		t.Fatal("Expected checksum mismatch error, got none")
+	}
+	if !strings.Contains(stderr.String(), "checksum mismatch") {
+		t.Errorf("Expected mismatch message on stderr, got %q", stderr.String())
+	}
+}
+
+func TestBase64_ChecksumAlgorithms(t *testing.T) {
+	tests := []struct {
+		name     string
+		checksum opt.Checksum
+		label    string
+	}{
+		{"sha256", opt.SHA256, "sha256="},
+		{"sha512", opt.SHA512, "sha512="},
+		{"blake2b", opt.BLAKE2b, "blake2b="},
+		{"crc32", opt.CRC32, "crc32="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			input := strings.NewReader("hello world")
+			var output, stderr bytes.Buffer
+
+			cmd := base64.Base64(tt.checksum, opt.Suffix)
+			if err := cmd.Execute(ctx, input, &output, &stderr); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !strings.Contains(output.String(), "# "+tt.label) {
+				t.Errorf("Expected %q comment in output, got %q", tt.label, output.String())
+			}
+
+			var decoded, decodeStderr bytes.Buffer
+			decodeCmd := base64.Base64(tt.checksum, opt.Suffix, opt.Decode)
+			if err := decodeCmd.Execute(ctx, strings.NewReader(output.String()), &decoded, &decodeStderr); err != nil {
+				t.Fatalf("Unexpected decode error: %v", err)
+			}
+			if got := decoded.String(); got != "hello world" {
+				t.Errorf("Expected %q, got %q", "hello world", got)
+			}
+		})
+	}
+}
+
+func TestBase64_ChecksumSidecarRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "myfile.txt")
+	if err := os.WriteFile(plain, []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	var encoded, encodeStderr bytes.Buffer
+	encodeCmd := base64.Base64(plain, opt.SHA256, opt.Sidecar)
+	if err := encodeCmd.Execute(ctx, nil, &encoded, &encodeStderr); err != nil {
+		t.Fatalf("Unexpected encode error: %v", err)
+	}
+
+	sidecar := plain + ".b64.sha256"
+	if _, err := os.Stat(sidecar); err != nil {
+		t.Fatalf("Expected sidecar file at %s: %v", sidecar, err)
+	}
+
+	encodedPath := plain + ".b64"
+	if err := os.WriteFile(encodedPath, encoded.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write encoded file: %v", err)
+	}
+
+	var decoded, decodeStderr bytes.Buffer
+	decodeCmd := base64.Base64(encodedPath, opt.SHA256, opt.Sidecar, opt.Decode)
+	if err := decodeCmd.Execute(ctx, nil, &decoded, &decodeStderr); err != nil {
+		t.Fatalf("Unexpected decode error: %v", err)
+	}
+	if got := decoded.String(); got != "hello world" {
+		t.Errorf("Expected %q, got %q", "hello world", got)
+	}
+}
+
+func TestBase64_ChecksumSidecarMissing(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	encodedPath := filepath.Join(dir, "myfile.txt.b64")
+	if err := os.WriteFile(encodedPath, []byte("aGVsbG8gd29ybGQ=\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write encoded file: %v", err)
+	}
+
+	var decoded, stderr bytes.Buffer
+	cmd := base64.Base64(encodedPath, opt.SHA256, opt.Sidecar, opt.Decode)
+	err := cmd.Execute(ctx, nil, &decoded, &stderr)
+
+	if err == nil {
+		t.Fatal("Expected an error for a missing sidecar file, got none")
+	}
+	if !strings.Contains(stderr.String(), "sidecar") {
+		t.Errorf("Expected sidecar-not-found message on stderr, got %q", stderr.String())
+	}
+}
+
+func TestBase64_ChecksumSuffixMissing(t *testing.T) {
+	ctx := context.Background()
+	// A validly encoded payload with no trailing checksum comment line.
+	plain := "aGVsbG8gd29ybGQ=\n"
+
+	var decoded, stderr bytes.Buffer
+	cmd := base64.Base64(opt.SHA256, opt.Suffix, opt.Decode)
+	err := cmd.Execute(ctx, strings.NewReader(plain), &decoded, &stderr)
+
+	if err == nil {
+		t.Fatal("Expected an error for a missing checksum comment line, got none")
+	}
+	if !strings.Contains(stderr.String(), "comment line") {
+		t.Errorf("Expected missing-comment message on stderr, got %q", stderr.String())
+	}
+}
+
 // Performance benchmarks
+func BenchmarkBase64_EncodeStreaming(b *testing.B) {
+	ctx := context.Background()
+	const inputSize = 64 * 1024 * 1024 // 64MB per iteration
+
+	b.ReportAllocs()
+	b.SetBytes(inputSize)
+	for i := 0; i < b.N; i++ {
+		cmd := base64.Base64()
+		if err := cmd.Execute(ctx, &zeroReader{remaining: inputSize}, io.Discard, io.Discard); err != nil {
+			b.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkBase64_DecodeStreaming(b *testing.B) {
+	ctx := context.Background()
+	const inputSize = 64 * 1024 * 1024 // 64MB per iteration
+
+	b.ReportAllocs()
+	b.SetBytes(inputSize)
+	for i := 0; i < b.N; i++ {
+		cmd := base64.Base64(opt.Decode)
+		if err := cmd.Execute(ctx, &zeroBase64Reader{remaining: inputSize}, io.Discard, io.Discard); err != nil {
+			b.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
 func BenchmarkBase64_Encode(b *testing.B) {
 	ctx := context.Background()
 	testData := strings.Repeat("test data ", 1000)
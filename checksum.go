@@ -0,0 +1,88 @@
+package base64
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"hash/crc32"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+
+	localopt "github.com/yupsh/base64/opt"
+)
+
+// checksumHashFor resolves the configured Checksum option to a hash.Hash
+// and its label (used for the comment/sidecar forms). ok is false when no
+// checksum was requested.
+func checksumHashFor(cs localopt.Checksum) (h hash.Hash, label string, ok bool) {
+	switch cs {
+	case localopt.SHA256:
+		return sha256.New(), "sha256", true
+	case localopt.SHA512:
+		return sha512.New(), "sha512", true
+	case localopt.BLAKE2b:
+		// nil key always succeeds for an unkeyed BLAKE2b-256 hash.
+		h, _ := blake2b.New256(nil)
+		return h, "blake2b", true
+	case localopt.CRC32:
+		return crc32.NewIEEE(), "crc32", true
+	default:
+		return nil, "", false
+	}
+}
+
+// checksumCommentPrefix is the prefix of the comment line emitted/expected
+// in Suffix mode, e.g. "# sha256=".
+func checksumCommentPrefix(label string) string {
+	return "# " + label + "="
+}
+
+// checksumSidecarPath is the path of the sidecar file in Sidecar mode,
+// e.g. "archive.tar.sha256".
+func checksumSidecarPath(name, label string) string {
+	return name + "." + label
+}
+
+func writeChecksumSidecar(name, label, digestHex string) error {
+	return os.WriteFile(checksumSidecarPath(name, label), []byte(digestHex+"\n"), 0o644)
+}
+
+// encodedArtifactName appends the canonical file extension for the given
+// framing/encoding to name, so Sidecar mode keys the checksum path to the
+// encoded artifact consistently on both sides of a round trip: decode's
+// name is already that of the encoded file it's reading, so encode must
+// predict the same name rather than using its plaintext input's name.
+func encodedArtifactName(name string, framing localopt.Framing, encoding localopt.Encoding) string {
+	if framing == localopt.PEM {
+		return name + ".pem"
+	}
+	switch encoding {
+	case localopt.Base32, localopt.Base32Hex:
+		return name + ".b32"
+	case localopt.Base16:
+		return name + ".hex"
+	case localopt.Ascii85:
+		return name + ".a85"
+	default: // StdBase64, URLBase64, RawStdBase64, RawURLBase64
+		return name + ".b64"
+	}
+}
+
+func readChecksumSidecar(name, label string) (string, bool) {
+	data, err := os.ReadFile(checksumSidecarPath(name, label))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// displayName returns the name used in checksum output/sidecar paths,
+// falling back to a stdin placeholder when the source has no file name.
+func displayName(name string) string {
+	if name == "" {
+		return "stdin"
+	}
+	return name
+}
@@ -0,0 +1,148 @@
+package base64
+
+import (
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+
+	localopt "github.com/yupsh/base64/opt"
+)
+
+// codec abstracts over the handful of text encodings the command supports,
+// so encodeSource/decodeSource don't need to know which one is in play.
+type codec interface {
+	Encode(src []byte) string
+	DecodeString(s string) ([]byte, error)
+	NewEncoder(w io.Writer) io.WriteCloser
+	NewDecoder(r io.Reader) io.Reader
+	// Charset reports which bytes are valid output characters for this
+	// codec, so IgnoreGarbage can filter alphabet-aware.
+	Charset() [256]bool
+}
+
+func charsetFromString(alphabet string) [256]bool {
+	var set [256]bool
+	for i := 0; i < len(alphabet); i++ {
+		set[alphabet[i]] = true
+	}
+	return set
+}
+
+// nopWriteCloser adapts encoders (like encoding/hex's) that don't need a
+// Close step to the io.WriteCloser shape the codec interface expects.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// base64Codec adapts *base64.Encoding to the codec interface.
+type base64Codec struct {
+	enc     *base64.Encoding
+	charset [256]bool
+}
+
+func newBase64Codec(enc *base64.Encoding, alphabet string, padded bool) base64Codec {
+	charset := charsetFromString(alphabet)
+	if padded {
+		charset['='] = true
+	}
+	return base64Codec{enc: enc, charset: charset}
+}
+
+func (c base64Codec) Encode(src []byte) string              { return c.enc.EncodeToString(src) }
+func (c base64Codec) DecodeString(s string) ([]byte, error) { return c.enc.DecodeString(s) }
+func (c base64Codec) NewEncoder(w io.Writer) io.WriteCloser { return base64.NewEncoder(c.enc, w) }
+func (c base64Codec) NewDecoder(r io.Reader) io.Reader      { return base64.NewDecoder(c.enc, r) }
+func (c base64Codec) Charset() [256]bool                    { return c.charset }
+
+// base32Codec adapts *base32.Encoding to the codec interface.
+type base32Codec struct {
+	enc     *base32.Encoding
+	charset [256]bool
+}
+
+func newBase32Codec(enc *base32.Encoding, alphabet string) base32Codec {
+	charset := charsetFromString(alphabet)
+	charset['='] = true
+	return base32Codec{enc: enc, charset: charset}
+}
+
+func (c base32Codec) Encode(src []byte) string              { return c.enc.EncodeToString(src) }
+func (c base32Codec) DecodeString(s string) ([]byte, error) { return c.enc.DecodeString(s) }
+func (c base32Codec) NewEncoder(w io.Writer) io.WriteCloser { return base32.NewEncoder(c.enc, w) }
+func (c base32Codec) NewDecoder(r io.Reader) io.Reader      { return base32.NewDecoder(c.enc, r) }
+func (c base32Codec) Charset() [256]bool                    { return c.charset }
+
+// hexCodec adapts encoding/hex to the codec interface.
+type hexCodec struct {
+	charset [256]bool
+}
+
+func newHexCodec() hexCodec {
+	return hexCodec{charset: charsetFromString("0123456789abcdefABCDEF")}
+}
+
+func (c hexCodec) Encode(src []byte) string              { return hex.EncodeToString(src) }
+func (c hexCodec) DecodeString(s string) ([]byte, error) { return hex.DecodeString(s) }
+func (c hexCodec) NewEncoder(w io.Writer) io.WriteCloser { return nopWriteCloser{hex.NewEncoder(w)} }
+func (c hexCodec) NewDecoder(r io.Reader) io.Reader      { return hex.NewDecoder(r) }
+func (c hexCodec) Charset() [256]bool                    { return c.charset }
+
+// ascii85Codec adapts encoding/ascii85 to the codec interface.
+type ascii85Codec struct {
+	charset [256]bool
+}
+
+func newAscii85Codec() ascii85Codec {
+	var set [256]bool
+	for b := byte('!'); b <= 'u'; b++ {
+		set[b] = true
+	}
+	set['z'] = true // shorthand for four zero bytes
+	return ascii85Codec{charset: set}
+}
+
+func (c ascii85Codec) Encode(src []byte) string {
+	dst := make([]byte, ascii85.MaxEncodedLen(len(src)))
+	n := ascii85.Encode(dst, src)
+	return string(dst[:n])
+}
+
+func (c ascii85Codec) DecodeString(s string) ([]byte, error) {
+	src := []byte(s)
+	dst := make([]byte, len(src))
+	n, _, err := ascii85.Decode(dst, src, true)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
+func (c ascii85Codec) NewEncoder(w io.Writer) io.WriteCloser { return ascii85.NewEncoder(w) }
+func (c ascii85Codec) NewDecoder(r io.Reader) io.Reader      { return ascii85.NewDecoder(r) }
+func (c ascii85Codec) Charset() [256]bool                    { return c.charset }
+
+// codecFor resolves the configured Encoding option to its codec implementation.
+func codecFor(encoding localopt.Encoding) codec {
+	switch encoding {
+	case localopt.URLBase64:
+		return newBase64Codec(base64.URLEncoding, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", true)
+	case localopt.RawStdBase64:
+		return newBase64Codec(base64.RawStdEncoding, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/", false)
+	case localopt.RawURLBase64:
+		return newBase64Codec(base64.RawURLEncoding, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", false)
+	case localopt.Base32:
+		return newBase32Codec(base32.StdEncoding, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567")
+	case localopt.Base32Hex:
+		return newBase32Codec(base32.HexEncoding, "0123456789ABCDEFGHIJKLMNOPQRSTUV")
+	case localopt.Base16:
+		return newHexCodec()
+	case localopt.Ascii85:
+		return newAscii85Codec()
+	default: // localopt.StdBase64
+		return newBase64Codec(base64.StdEncoding, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/", true)
+	}
+}
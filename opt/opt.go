@@ -25,12 +25,66 @@ const (
 	NoWrap WrapFlag = false
 )
 
+// Framing selects whether the encoded output is wrapped in an interchange
+// envelope (MIME-style or PEM per RFC 7468) or left bare.
+type Framing int
+
+const (
+	NoFrame Framing = iota // Bare encoded output (default)
+	MIME                   // CRLF-wrapped lines, no header/footer
+	PEM                    // CRLF-wrapped lines inside a BEGIN/END envelope
+)
+
+// Label names the PEM envelope, e.g. "CERTIFICATE" or "PRIVATE KEY",
+// producing "-----BEGIN <LABEL>-----" / "-----END <LABEL>-----" lines.
+type Label string
+
+// Encoding selects the alphabet used to encode/decode data.
+type Encoding int
+
+const (
+	StdBase64    Encoding = iota // RFC 4648 standard base64, padded
+	URLBase64                    // RFC 4648 URL/filename-safe base64, padded
+	RawStdBase64                 // Standard base64, unpadded
+	RawURLBase64                 // URL-safe base64, unpadded
+	Base32                       // RFC 4648 base32
+	Base32Hex                    // RFC 4648 base32 with extended hex alphabet
+	Base16                       // Hex encoding (RFC 4648 base16)
+	Ascii85                      // btoa/Adobe ascii85
+)
+
+// Checksum selects a digest algorithm to compute over the decoded bytes
+// alongside encoding/decoding, for integrity verification.
+type Checksum int
+
+const (
+	NoChecksum Checksum = iota // No checksum computed (default)
+	SHA256
+	SHA512
+	BLAKE2b
+	CRC32
+)
+
+// ChecksumOutput selects where the computed checksum is recorded.
+type ChecksumOutput int
+
+const (
+	Stderr  ChecksumOutput = iota // Print "base64: <digest>  <file>" to stderr (default)
+	Suffix                        // Append "# <algo>=<digest>" after the encoded block
+	Sidecar                       // Write "<encoded file>.<algo>", e.g. "<file>.b64.sha256" when encoding <file>
+)
+
 // Flags represents the configuration options for the base64 command
 type Flags struct {
-	Decode        DecodeFlag        // Decode data (-d)
-	IgnoreGarbage IgnoreGarbageFlag // Ignore non-alphabet characters when decoding (-i)
-	Wrap          WrapFlag          // Wrap encoded lines after COLS character (default 76)
-	WrapWidth     WrapWidth         // Wrap width (-w)
+	Decode         DecodeFlag        // Decode data (-d)
+	IgnoreGarbage  IgnoreGarbageFlag // Ignore non-alphabet characters when decoding (-i)
+	Wrap           WrapFlag          // Wrap encoded lines after COLS character (default 76)
+	WrapWidth      WrapWidth         // Wrap width (-w)
+	Encoding       Encoding          // Alphabet to encode/decode with (default StdBase64)
+	Framing        Framing           // Interchange envelope (default NoFrame)
+	Label          Label             // PEM envelope label
+	Checksum       Checksum          // Digest algorithm computed over the decoded bytes
+	ChecksumOutput ChecksumOutput    // Where the checksum is recorded (default Stderr)
 }
 
 // Configure methods for the opt system
@@ -38,3 +92,8 @@ func (d DecodeFlag) Configure(flags *Flags)        { flags.Decode = d }
 func (i IgnoreGarbageFlag) Configure(flags *Flags) { flags.IgnoreGarbage = i }
 func (w WrapFlag) Configure(flags *Flags)          { flags.Wrap = w }
 func (w WrapWidth) Configure(flags *Flags)         { flags.WrapWidth = w }
+func (e Encoding) Configure(flags *Flags)          { flags.Encoding = e }
+func (f Framing) Configure(flags *Flags)           { flags.Framing = f }
+func (l Label) Configure(flags *Flags)             { flags.Label = l }
+func (c Checksum) Configure(flags *Flags)          { flags.Checksum = c }
+func (o ChecksumOutput) Configure(flags *Flags)    { flags.ChecksumOutput = o }